@@ -1,171 +1,177 @@
-package main
-
-import (
-	"encoding/csv"
-	"fmt"
-	"math"
-	"os"
-	"strconv"
-	"sync"
-	"time"
-)
-
-// ----------- Funciones comunes -----------
-
-func sigmoid(z float64) float64 {
-	return 1.0 / (1.0 + math.Exp(-z))
-}
-
-func predict(X []float64, weights []float64) float64 {
-	var z float64
-	for i := 0; i < len(X); i++ {
-		z += X[i] * weights[i]
-	}
-	return sigmoid(z)
-}
-
-func loadCSVData(path string) ([][]float64, []float64, float64, float64, float64, float64, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, nil, 0, 0, 0, 0, err
-	}
-	defer file.Close()
-
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, nil, 0, 0, 0, 0, err
-	}
-
-	var X [][]float64
-	var y []float64
-	minRating, maxRating := math.MaxFloat64, -math.MaxFloat64
-	minReviews, maxReviews := math.MaxFloat64, -math.MaxFloat64
-
-	for i, row := range records {
-		if i == 0 {
-			continue
-		}
-		rating, err1 := strconv.ParseFloat(row[5], 64)
-		numReviews, err2 := strconv.ParseFloat(row[6], 64)
-		if err1 != nil || err2 != nil {
-			continue
-		}
-
-		if rating < minRating {
-			minRating = rating
-		}
-		if rating > maxRating {
-			maxRating = rating
-		}
-		if numReviews < minReviews {
-			minReviews = numReviews
-		}
-		if numReviews > maxReviews {
-			maxReviews = numReviews
-		}
-
-		xi := []float64{1, rating, numReviews}
-		X = append(X, xi)
-
-		label := 0.0
-		if rating >= 4.0 {
-			label = 1.0
-		}
-		y = append(y, label)
-	}
-	return X, y, minRating, maxRating, minReviews, maxReviews, nil
-}
-
-func normalizeFeatures(X [][]float64, minRating, maxRating, minReviews, maxReviews float64) {
-	for i := 0; i < len(X); i++ {
-		X[i][1] = (X[i][1] - minRating) / (maxRating - minRating)
-		X[i][2] = (X[i][2] - minReviews) / (maxReviews - minReviews)
-	}
-}
-
-// ----------- Entrenamiento Concurrente -----------
-
-func trainConcurrent(X [][]float64, y []float64, learningRate float64, iterations int, batchSize int) []float64 {
-	features := len(X[0])
-	weights := make([]float64, features)
-	dataLen := len(X)
-
-	for iter := 0; iter < iterations; iter++ {
-		var wg sync.WaitGroup
-		var mutex sync.Mutex
-
-		for i := 0; i < dataLen; i += batchSize {
-			wg.Add(1)
-
-			start := i
-			end := i + batchSize
-			if end > dataLen {
-				end = dataLen
-			}
-
-			go func(start, end int) {
-				defer wg.Done()
-				partialGradients := make([]float64, features)
-
-				for j := start; j < end; j++ {
-					pred := predict(X[j], weights)
-					error := pred - y[j]
-					for k := 0; k < features; k++ {
-						partialGradients[k] += error * X[j][k]
-					}
-				}
-
-				mutex.Lock()
-				for k := 0; k < features; k++ {
-					weights[k] -= learningRate * partialGradients[k] / float64(end-start)
-				}
-				mutex.Unlock()
-			}(start, end)
-		}
-
-		wg.Wait()
-	}
-	return weights
-}
-
-
-func calculateAccuracy(X [][]float64, y []float64, weights []float64) float64 {
-	correct := 0
-	for i := 0; i < len(X); i++ {
-		pred := predict(X[i], weights)
-		if (pred >= 0.5 && y[i] == 1.0) || (pred < 0.5 && y[i] == 0.0) {
-			correct++
-		}
-	}
-	return float64(correct) / float64(len(X)) * 100
-}
-
-func main() {
-	// Cargar datos desde el CSV
-	X, y, minRating, maxRating, minReviews, maxReviews, err := loadCSVData("yelp_database.csv")
-	if err != nil {
-		fmt.Println("Error al cargar datos:", err)
-		return
-	}
-	normalizeFeatures(X, minRating, maxRating, minReviews, maxReviews)
-
-	// Configuración de parámetros
-	learningRate := 0.1
-	iterations := 1000
-	batchSize := 100
-
-	// Entrenamiento concurrente
-	start := time.Now()
-	weights := trainConcurrent(X, y, learningRate, iterations, batchSize)
-	duration := time.Since(start)
-
-	// Cálculo de precisión
-	accuracy := calculateAccuracy(X, y, weights)
-
-	// Mostrar resultados
-	fmt.Println("--- Modo Concurrente ---")
-	fmt.Printf("Pesos: %v\n", weights)
-	fmt.Printf("Precisión: %.2f%%\n", accuracy)
-	fmt.Printf("Tiempo de ejecución: %v\n", duration)
-}
+package main
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	"tpconcurrente/data"
+	"tpconcurrente/optim"
+)
+
+// ----------- Funciones comunes -----------
+
+func sigmoid(z float64) float64 {
+	return 1.0 / (1.0 + math.Exp(-z))
+}
+
+func predict(X []float64, weights []float64) float64 {
+	var z float64
+	for i := 0; i < len(X); i++ {
+		z += X[i] * weights[i]
+	}
+	return sigmoid(z)
+}
+
+// ----------- Entrenamiento Concurrente -----------
+
+// UpdateMode selects how often trainConcurrent applies a weight update.
+type UpdateMode int
+
+const (
+	// PerMinibatch applies one update per mini-batch, i.e. stochastic
+	// mini-batch gradient descent.
+	PerMinibatch UpdateMode = iota
+	// PerEpoch accumulates the gradient over the whole dataset before
+	// applying a single update, i.e. true batch gradient descent.
+	PerEpoch
+)
+
+// rowRange is a [start, end) slice of row indices dispatched to a worker.
+type rowRange struct {
+	start, end int
+}
+
+// trainConcurrent usa un pool fijo de runtime.GOMAXPROCS(0) workers que
+// toman rangos de filas de un canal con buffer y acumulan su propio
+// gradiente parcial, sin mutex en el loop interno.
+func trainConcurrent(X [][]float64, y []float64, opt optim.Optimizer, reg optim.Regularizer, iterations int, batchSize int, mode UpdateMode) []float64 {
+	features := len(X[0])
+	weights := make([]float64, features)
+	dataLen := len(X)
+
+	workers := runtime.GOMAXPROCS(0)
+	jobs := make(chan rowRange, workers)
+	partials := make([][]float64, workers)
+	for w := range partials {
+		partials[w] = make([]float64, features)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		go func(id int) {
+			grad := partials[id]
+			for r := range jobs {
+				for j := r.start; j < r.end; j++ {
+					pred := predict(X[j], weights)
+					error := pred - y[j]
+					for k := 0; k < features; k++ {
+						grad[k] += error * X[j][k]
+					}
+				}
+				wg.Done()
+			}
+		}(w)
+	}
+	defer close(jobs)
+
+	computeGradient := func(lo, hi int) []float64 {
+		for _, p := range partials {
+			for k := range p {
+				p[k] = 0
+			}
+		}
+
+		n := hi - lo
+		chunkSize := (n + workers - 1) / workers
+		for start := lo; start < hi; start += chunkSize {
+			end := start + chunkSize
+			if end > hi {
+				end = hi
+			}
+			wg.Add(1)
+			jobs <- rowRange{start, end}
+		}
+		wg.Wait()
+
+		grad := make([]float64, features)
+		for _, p := range partials {
+			for k := range p {
+				grad[k] += p[k]
+			}
+		}
+		for k := range grad {
+			grad[k] /= float64(n)
+		}
+		return grad
+	}
+
+	for iter := 0; iter < iterations; iter++ {
+		switch mode {
+		case PerEpoch:
+			grad := computeGradient(0, dataLen)
+			if reg != nil {
+				reg.Penalize(weights, grad)
+			}
+			opt.Step(weights, grad)
+		default: // PerMinibatch
+			for i := 0; i < dataLen; i += batchSize {
+				end := i + batchSize
+				if end > dataLen {
+					end = dataLen
+				}
+				grad := computeGradient(i, end)
+				if reg != nil {
+					reg.Penalize(weights, grad)
+				}
+				opt.Step(weights, grad)
+			}
+		}
+	}
+	return weights
+}
+
+func calculateAccuracy(X [][]float64, y []float64, weights []float64) float64 {
+	correct := 0
+	for i := 0; i < len(X); i++ {
+		pred := predict(X[i], weights)
+		if (pred >= 0.5 && y[i] == 1.0) || (pred < 0.5 && y[i] == 0.0) {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(X)) * 100
+}
+
+func main() {
+	// Cargar datos desde el CSV con el loader en streaming
+	loader := data.NewStreamingLoader()
+	X, y, err := loader.Load("yelp_database.csv")
+	if err != nil {
+		fmt.Println("Error al cargar datos:", err)
+		return
+	}
+	loader.Normalize(X, data.MinMax)
+
+	// Configuración de parámetros
+	iterations := 1000
+	batchSize := 100
+	opt := &optim.SGD{LR: 0.1}
+	reg := optim.L2{Lambda: 0.01}
+
+	// Entrenamiento concurrente
+	start := time.Now()
+	weights := trainConcurrent(X, y, opt, reg, iterations, batchSize, PerMinibatch)
+	duration := time.Since(start)
+
+	// Cálculo de precisión
+	accuracy := calculateAccuracy(X, y, weights)
+
+	// Mostrar resultados
+	fmt.Println("--- Modo Concurrente ---")
+	fmt.Printf("Pesos: %v\n", weights)
+	fmt.Printf("Precisión: %.2f%%\n", accuracy)
+	fmt.Printf("Pérdida L2: %.6f\n", reg.Loss(weights))
+	fmt.Printf("Tiempo de ejecución: %v\n", duration)
+}
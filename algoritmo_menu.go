@@ -1,313 +1,332 @@
-package main
-
-import (
-	"bufio"
-	"encoding/csv"
-	"fmt"
-	"math"
-	"os"
-	"strconv"
-	"strings"
-	"sync"
-	"time"
-)
-
-// ----------- Funciones comunes -----------
-
-func sigmoid(z float64) float64 {
-	return 1.0 / (1.0 + math.Exp(-z))
-}
-
-func predict(X []float64, weights []float64) float64 {
-	var z float64
-	for i := 0; i < len(X); i++ {
-		z += X[i] * weights[i]
-	}
-	return sigmoid(z)
-}
-
-func loadCSVData(path string) (trainX, testX [][]float64, trainY, testY []float64, minRating, maxRating, minReviews, maxReviews float64, err error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return
-	}
-	defer file.Close()
-
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
-	if err != nil {
-		return
-	}
-
-	var allX [][]float64
-	var allY []float64
-	minRating, maxRating = math.MaxFloat64, -math.MaxFloat64
-	minReviews, maxReviews = math.MaxFloat64, -math.MaxFloat64
-
-	for i, row := range records {
-		if i == 0 {
-			continue
-		}
-		rating, err1 := strconv.ParseFloat(row[5], 64)
-		numReviews, err2 := strconv.ParseFloat(row[6], 64)
-		if err1 != nil || err2 != nil {
-			continue
-		}
-
-		if rating < minRating {
-			minRating = rating
-		}
-		if rating > maxRating {
-			maxRating = rating
-		}
-		if numReviews < minReviews {
-			minReviews = numReviews
-		}
-		if numReviews > maxReviews {
-			maxReviews = numReviews
-		}
-
-		xi := []float64{1, rating, numReviews}
-		allX = append(allX, xi)
-
-		label := 0.0
-		if rating >= 4.0 {
-			label = 1.0
-		}
-		allY = append(allY, label)
-	}
-
-	// Dividir 80/20
-	total := len(allX)
-	split := int(0.8 * float64(total))
-	for i := 0; i < split; i++ {
-		trainX = append(trainX, allX[i])
-		trainY = append(trainY, allY[i])
-	}
-	for i := split; i < total; i++ {
-		testX = append(testX, allX[i])
-		testY = append(testY, allY[i])
-	}
-
-	return
-}
-
-func normalizeFeatures(X [][]float64, minRating, maxRating, minReviews, maxReviews float64) {
-	for i := 0; i < len(X); i++ {
-		X[i][1] = (X[i][1] - minRating) / (maxRating - minRating)
-		X[i][2] = (X[i][2] - minReviews) / (maxReviews - minReviews)
-	}
-}
-
-// ----------- Entrenamiento secuencial -----------
-
-func trainSequential(X [][]float64, y []float64, learningRate float64, iterations int, batchSize int) []float64 {
-	features := len(X[0])
-	weights := make([]float64, features)
-	dataLen := len(X)
-
-	for iter := 0; iter < iterations; iter++ {
-		for i := 0; i < dataLen; i += batchSize {
-			end := i + batchSize
-			if end > dataLen {
-				end = dataLen
-			}
-
-			gradients := make([]float64, features)
-			for j := i; j < end; j++ {
-				pred := predict(X[j], weights)
-				error := pred - y[j]
-				for k := 0; k < features; k++ {
-					gradients[k] += error * X[j][k]
-				}
-			}
-
-			for k := 0; k < features; k++ {
-				weights[k] -= learningRate * gradients[k] / float64(end-i)
-			}
-		}
-	}
-	return weights
-}
-
-// ----------- Entrenamiento concurrente optimizado con minibatches -----------
-func trainConcurrent(X [][]float64, y []float64, learningRate float64, iterations int, batchSize int) []float64 {
-	features := len(X[0])
-	weights := make([]float64, features)
-	dataLen := len(X)
-
-	for iter := 0; iter < iterations; iter++ {
-		var wg sync.WaitGroup
-		var mutex sync.Mutex
-
-		// Recorremos los mini-batches como en la secuencial
-		for i := 0; i < dataLen; i += batchSize {
-			wg.Add(1)
-
-			// Capturar valores para la goroutine
-			start := i
-			end := i + batchSize
-			if end > dataLen {
-				end = dataLen
-			}
-
-			go func(start, end int) {
-				defer wg.Done()
-				partialGradients := make([]float64, features)
-
-				for j := start; j < end; j++ {
-					pred := predict(X[j], weights)
-					error := pred - y[j]
-					for k := 0; k < features; k++ {
-						partialGradients[k] += error * X[j][k]
-					}
-				}
-
-				mutex.Lock()
-				for k := 0; k < features; k++ {
-					weights[k] -= learningRate * partialGradients[k] / float64(end-start)
-				}
-				mutex.Unlock()
-			}(start, end)
-		}
-
-		wg.Wait()
-	}
-	return weights
-}
-
-func trimmedMean(times []float64, trimCount int) float64 {
-	if len(times) <= 2*trimCount {
-		panic("No hay suficientes datos para calcular la media recortada.")
-	}
-	sort.Float64s(times)
-	trimmed := times[trimCount : len(times)-trimCount]
-
-	var sum float64
-	for _, t := range trimmed {
-		sum += t
-	}
-	return sum / float64(len(trimmed))
-}
-
-// Función para calcular la precisión
-
-func calculateAccuracy(X [][]float64, y []float64, weights []float64) float64 {
-	correct := 0
-	for i := 0; i < len(X); i++ {
-		pred := predict(X[i], weights)
-		if (pred >= 0.5 && y[i] == 1.0) || (pred < 0.5 && y[i] == 0.0) {
-			correct++
-		}
-	}
-	return float64(correct) / float64(len(X)) * 100
-}
-
-// ----------- Menú principal -----------
-
-func main() {
-	trainX, testX, trainY, testY, minRating, maxRating, minReviews, maxReviews, err := loadCSVData("yelp_database.csv")
-	if err != nil {
-		fmt.Println("Error al cargar datos:", err)
-		return
-	}
-	normalizeFeatures(trainX, minRating, maxRating, minReviews, maxReviews)
-	normalizeFeatures(testX, minRating, maxRating, minReviews, maxReviews)
-
-	learningRate := 0.1
-	iterations := 750
-	batchSize := 100 // Tamaño del minibatch
-
-	// Normalizar muestra manualmente
-	rawMuestra := []float64{1, 4.2, 120}
-	rawMuestra[1] = (rawMuestra[1] - minRating) / (maxRating - minRating)
-	rawMuestra[2] = (rawMuestra[2] - minReviews) / (maxReviews - minReviews)
-
-	reader := bufio.NewReader(os.Stdin)
-
-	for {
-		fmt.Println("\n======= MENÚ =======")
-		fmt.Println("1. Entrenar (Secuencial)")
-		fmt.Println("2. Entrenar (Concurrente)")
-		fmt.Println("3. Comparar ambos")
-		fmt.Println("4. Benchmark")
-		fmt.Println("5. Salir")
-		fmt.Print("Seleccione una opción: ")
-
-		input, _ := reader.ReadString('\n')
-		choice := strings.TrimSpace(input)
-
-		switch choice {
-		case "1":
-			start := time.Now()
-			weights := trainSequential(trainX, trainY, learningRate, iterations, batchSize)
-			duration := time.Since(start)
-			accuracy := calculateAccuracy(testX, testY, weights)
-			fmt.Println("\n--- Modo Secuencial ---")
-			fmt.Println("Pesos:", weights)
-			fmt.Printf("Probabilidad ejemplo: %.4f\n", predict(rawMuestra, weights))
-			fmt.Printf("Precisión (test): %.2f%%\n", accuracy)
-			fmt.Println("Tiempo:", duration)
-		case "2":
-			start := time.Now()
-			weights := trainConcurrent(trainX, trainY, learningRate, iterations, batchSize)
-			duration := time.Since(start)
-			accuracy := calculateAccuracy(testX, testY, weights)
-			fmt.Println("\n--- Modo Concurrente ---")
-			fmt.Println("Pesos:", weights)
-			fmt.Printf("Probabilidad ejemplo: %.4f\n", predict(rawMuestra, weights))
-			fmt.Printf("Precisión (test): %.2f%%\n", accuracy)
-			fmt.Println("Tiempo:", duration)
-		case "3":
-			startSeq := time.Now()
-			weightsSeq := trainSequential(trainX, trainY, learningRate, iterations, batchSize)
-			durSeq := time.Since(startSeq)
-			accuracySeq := calculateAccuracy(testX, testY, weightsSeq)
-
-			startConc := time.Now()
-			weightsConc := trainConcurrent(trainX, trainY, learningRate, iterations, batchSize)
-			durConc := time.Since(startConc)
-			accuracyConc := calculateAccuracy(testX, testY, weightsConc)
-
-			fmt.Println("\n--- Comparación ---")
-			fmt.Printf("Secuencial: Tiempo: %v | Precisión (test): %.2f%% | Probabilidad: %.4f\n",
-				durSeq, accuracySeq, predict(rawMuestra, weightsSeq))
-			fmt.Printf("Concurrente: Tiempo: %v | Precisión (test): %.2f%% | Probabilidad: %.4f\n",
-				durConc, accuracyConc, predict(rawMuestra, weightsConc))
-		case "4":
-			const total = 1000
-			var timesSeq []float64
-			var timesConc []float64
-
-			fmt.Println("\n--- Iniciando benchmark de 1000 repeticiones ---")
-			for i := 0; i < total; i++ {
-				startSeq := time.Now()
-				_ = trainSequential(trainX, trainY, learningRate, iterations, batchSize)
-				timesSeq = append(timesSeq, time.Since(startSeq).Seconds())
-
-				startConc := time.Now()
-				_ = trainConcurrent(trainX, trainY, learningRate, iterations, batchSize)
-				timesConc = append(timesConc, time.Since(startConc).Seconds())
-
-				// Mostrar progreso cada 10 iteraciones
-				if (i+1)%10 == 0 || i == total-1 {
-					percent := float64(i+1) / float64(total) * 100
-					fmt.Printf("\rProgreso: %4.1f%% [%d/%d]", percent, i+1, total)
-				}
-			}
-			fmt.Println() // salto de línea
-
-			meanSeq := trimmedMean(timesSeq, 50)
-			meanConc := trimmedMean(timesConc, 50)
-
-			fmt.Println("\n--- Benchmark completado ---")
-			fmt.Printf("Media recortada Secuencial (seg): %.4f\n", meanSeq)
-			fmt.Printf("Media recortada Concurrente (seg): %.4f\n", meanConc)
-		case "5":
-			fmt.Println("Saliendo...")
-			return
-		default:
-			fmt.Println("Opción inválida.")
-		}
-	}
-}
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"tpconcurrente/bench"
+	"tpconcurrente/data"
+	"tpconcurrente/dataset"
+	"tpconcurrente/optim"
+)
+
+// ----------- Funciones comunes -----------
+
+func sigmoid(z float64) float64 {
+	return 1.0 / (1.0 + math.Exp(-z))
+}
+
+func predict(X []float64, weights []float64) float64 {
+	var z float64
+	for i := 0; i < len(X); i++ {
+		z += X[i] * weights[i]
+	}
+	return sigmoid(z)
+}
+
+// splitSeed seeds the stratified shuffle in loadCSVData and the
+// k-fold cross-validation menu option, so runs are reproducible.
+const splitSeed = 42
+
+// loadCSVData streams path with a data.StreamingLoader, normalizes the
+// result and stratified-splits it 80/20 into train/test sets so both
+// partitions keep the overall class balance, regardless of whatever
+// order the CSV rows happen to be in.
+func loadCSVData(path string) (fullX [][]float64, fullY []float64, trainX, testX [][]float64, trainY, testY []float64, loader *data.StreamingLoader, err error) {
+	loader = data.NewStreamingLoader()
+	fullX, fullY, err = loader.Load(path)
+	if err != nil {
+		return
+	}
+	loader.Normalize(fullX, data.MinMax)
+
+	folds, foldsY := dataset.StratifiedSplit(fullX, fullY, []float64{0.8, 0.2}, splitSeed)
+	trainX, testX = folds[0], folds[1]
+	trainY, testY = foldsY[0], foldsY[1]
+
+	return
+}
+
+// ----------- Entrenamiento secuencial -----------
+
+func trainSequential(X [][]float64, y []float64, opt optim.Optimizer, reg optim.Regularizer, iterations int, batchSize int) []float64 {
+	features := len(X[0])
+	weights := make([]float64, features)
+	dataLen := len(X)
+
+	for iter := 0; iter < iterations; iter++ {
+		for i := 0; i < dataLen; i += batchSize {
+			end := i + batchSize
+			if end > dataLen {
+				end = dataLen
+			}
+
+			gradients := make([]float64, features)
+			for j := i; j < end; j++ {
+				pred := predict(X[j], weights)
+				error := pred - y[j]
+				for k := 0; k < features; k++ {
+					gradients[k] += error * X[j][k]
+				}
+			}
+
+			for k := 0; k < features; k++ {
+				gradients[k] /= float64(end - i)
+			}
+			if reg != nil {
+				reg.Penalize(weights, gradients)
+			}
+			opt.Step(weights, gradients)
+		}
+	}
+	return weights
+}
+
+// UpdateMode selects how often trainConcurrent applies a weight update.
+type UpdateMode int
+
+const (
+	// PerMinibatch applies one update per mini-batch, i.e. stochastic
+	// mini-batch gradient descent.
+	PerMinibatch UpdateMode = iota
+	// PerEpoch accumulates the gradient over the whole dataset before
+	// applying a single update, i.e. true batch gradient descent.
+	PerEpoch
+)
+
+// rowRange is a [start, end) slice of row indices dispatched to a worker.
+type rowRange struct {
+	start, end int
+}
+
+// ----------- Entrenamiento concurrente optimizado con minibatches -----------
+// Pool fijo de workers (sin mutex en el loop interno; ver algoritmo_concurrente.go)
+func trainConcurrent(X [][]float64, y []float64, opt optim.Optimizer, reg optim.Regularizer, iterations int, batchSize int, mode UpdateMode) []float64 {
+	features := len(X[0])
+	weights := make([]float64, features)
+	dataLen := len(X)
+
+	workers := runtime.GOMAXPROCS(0)
+	jobs := make(chan rowRange, workers)
+	partials := make([][]float64, workers)
+	for w := range partials {
+		partials[w] = make([]float64, features)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		go func(id int) {
+			grad := partials[id]
+			for r := range jobs {
+				for j := r.start; j < r.end; j++ {
+					pred := predict(X[j], weights)
+					error := pred - y[j]
+					for k := 0; k < features; k++ {
+						grad[k] += error * X[j][k]
+					}
+				}
+				wg.Done()
+			}
+		}(w)
+	}
+	defer close(jobs)
+
+	computeGradient := func(lo, hi int) []float64 {
+		for _, p := range partials {
+			for k := range p {
+				p[k] = 0
+			}
+		}
+
+		n := hi - lo
+		chunkSize := (n + workers - 1) / workers
+		for start := lo; start < hi; start += chunkSize {
+			end := start + chunkSize
+			if end > hi {
+				end = hi
+			}
+			wg.Add(1)
+			jobs <- rowRange{start, end}
+		}
+		wg.Wait()
+
+		grad := make([]float64, features)
+		for _, p := range partials {
+			for k := range p {
+				grad[k] += p[k]
+			}
+		}
+		for k := range grad {
+			grad[k] /= float64(n)
+		}
+		return grad
+	}
+
+	for iter := 0; iter < iterations; iter++ {
+		switch mode {
+		case PerEpoch:
+			grad := computeGradient(0, dataLen)
+			if reg != nil {
+				reg.Penalize(weights, grad)
+			}
+			opt.Step(weights, grad)
+		default: // PerMinibatch
+			for i := 0; i < dataLen; i += batchSize {
+				end := i + batchSize
+				if end > dataLen {
+					end = dataLen
+				}
+				grad := computeGradient(i, end)
+				if reg != nil {
+					reg.Penalize(weights, grad)
+				}
+				opt.Step(weights, grad)
+			}
+		}
+	}
+	return weights
+}
+
+// Función para calcular la precisión
+
+func calculateAccuracy(X [][]float64, y []float64, weights []float64) float64 {
+	correct := 0
+	for i := 0; i < len(X); i++ {
+		pred := predict(X[i], weights)
+		if (pred >= 0.5 && y[i] == 1.0) || (pred < 0.5 && y[i] == 0.0) {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(X)) * 100
+}
+
+// ----------- Menú principal -----------
+
+func main() {
+	fullX, fullY, trainX, testX, trainY, testY, loader, err := loadCSVData("yelp_database.csv")
+	if err != nil {
+		fmt.Println("Error al cargar datos:", err)
+		return
+	}
+
+	iterations := 750
+	batchSize := 100 // Tamaño del minibatch
+	newOptimizer := func() optim.Optimizer { return optim.NewAdam(0.05) }
+	reg := optim.L2{Lambda: 0.01}
+
+	// Normalizar muestra manualmente con las mismas estadísticas del loader
+	rawMuestra := []float64{1, 4.2, 120}
+	rawMuestra[1] = (rawMuestra[1] - loader.Rating.Min) / (loader.Rating.Max - loader.Rating.Min)
+	rawMuestra[2] = (rawMuestra[2] - loader.NumReviews.Min) / (loader.NumReviews.Max - loader.NumReviews.Min)
+
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		fmt.Println("\n======= MENÚ =======")
+		fmt.Println("1. Entrenar (Secuencial)")
+		fmt.Println("2. Entrenar (Concurrente)")
+		fmt.Println("3. Comparar ambos")
+		fmt.Println("4. Benchmark")
+		fmt.Println("5. Validación cruzada (k-fold)")
+		fmt.Println("6. Salir")
+		fmt.Print("Seleccione una opción: ")
+
+		input, _ := reader.ReadString('\n')
+		choice := strings.TrimSpace(input)
+
+		switch choice {
+		case "1":
+			start := time.Now()
+			weights := trainSequential(trainX, trainY, newOptimizer(), reg, iterations, batchSize)
+			duration := time.Since(start)
+			accuracy := calculateAccuracy(testX, testY, weights)
+			fmt.Println("\n--- Modo Secuencial ---")
+			fmt.Println("Pesos:", weights)
+			fmt.Printf("Probabilidad ejemplo: %.4f\n", predict(rawMuestra, weights))
+			fmt.Printf("Precisión (test): %.2f%%\n", accuracy)
+			fmt.Printf("Pérdida L2: %.6f\n", reg.Loss(weights))
+			fmt.Println("Tiempo:", duration)
+		case "2":
+			start := time.Now()
+			weights := trainConcurrent(trainX, trainY, newOptimizer(), reg, iterations, batchSize, PerMinibatch)
+			duration := time.Since(start)
+			accuracy := calculateAccuracy(testX, testY, weights)
+			fmt.Println("\n--- Modo Concurrente ---")
+			fmt.Println("Pesos:", weights)
+			fmt.Printf("Probabilidad ejemplo: %.4f\n", predict(rawMuestra, weights))
+			fmt.Printf("Precisión (test): %.2f%%\n", accuracy)
+			fmt.Printf("Pérdida L2: %.6f\n", reg.Loss(weights))
+			fmt.Println("Tiempo:", duration)
+		case "3":
+			startSeq := time.Now()
+			weightsSeq := trainSequential(trainX, trainY, newOptimizer(), reg, iterations, batchSize)
+			durSeq := time.Since(startSeq)
+			accuracySeq := calculateAccuracy(testX, testY, weightsSeq)
+
+			startConc := time.Now()
+			weightsConc := trainConcurrent(trainX, trainY, newOptimizer(), reg, iterations, batchSize, PerMinibatch)
+			durConc := time.Since(startConc)
+			accuracyConc := calculateAccuracy(testX, testY, weightsConc)
+
+			fmt.Println("\n--- Comparación ---")
+			fmt.Printf("Secuencial: Tiempo: %v | Precisión (test): %.2f%% | Probabilidad: %.4f\n",
+				durSeq, accuracySeq, predict(rawMuestra, weightsSeq))
+			fmt.Printf("Concurrente: Tiempo: %v | Precisión (test): %.2f%% | Probabilidad: %.4f\n",
+				durConc, accuracyConc, predict(rawMuestra, weightsConc))
+		case "4":
+			const total = 1000
+			var timesSeq []time.Duration
+			var timesConc []time.Duration
+
+			fmt.Println("\n--- Iniciando benchmark de 1000 repeticiones ---")
+			for i := 0; i < total; i++ {
+				startSeq := time.Now()
+				_ = trainSequential(trainX, trainY, newOptimizer(), reg, iterations, batchSize)
+				timesSeq = append(timesSeq, time.Since(startSeq))
+
+				startConc := time.Now()
+				_ = trainConcurrent(trainX, trainY, newOptimizer(), reg, iterations, batchSize, PerMinibatch)
+				timesConc = append(timesConc, time.Since(startConc))
+
+				// Mostrar progreso cada 10 iteraciones
+				if (i+1)%10 == 0 || i == total-1 {
+					percent := float64(i+1) / float64(total) * 100
+					fmt.Printf("\rProgreso: %4.1f%% [%d/%d]", percent, i+1, total)
+				}
+			}
+			fmt.Println() // salto de línea
+
+			comparison := bench.Compare(timesSeq, timesConc)
+			fmt.Println("\n--- Benchmark completado ---")
+			comparison.Print()
+
+			csv := strings.Join(comparison.CSVRows(), "\n") + "\n"
+			if err := os.WriteFile("bench_output.txt", []byte(csv), 0644); err != nil {
+				fmt.Println("No se pudo escribir bench_output.txt:", err)
+			} else {
+				fmt.Println("Resultados exportados a bench_output.txt")
+			}
+		case "5":
+			const k = 5
+			meanAcc, stdAcc := dataset.KFoldCV(fullX, fullY, k, splitSeed,
+				func(trainX [][]float64, trainY []float64) []float64 {
+					return trainSequential(trainX, trainY, newOptimizer(), reg, iterations, batchSize)
+				},
+				predict,
+			)
+			fmt.Printf("\n--- Validación cruzada %d-fold ---\n", k)
+			fmt.Printf("Precisión: %.2f%% ± %.2f%%\n", meanAcc, stdAcc)
+		case "6":
+			fmt.Println("Saliendo...")
+			return
+		default:
+			fmt.Println("Opción inválida.")
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"tpconcurrente/optim"
+)
+
+// TestTrainConcurrentMatchesSequentialPerEpoch checks the property the
+// chunk0-5 worker-pool rewrite of trainConcurrent is supposed to
+// preserve: under PerEpoch, splitting the full-batch gradient across a
+// pool of workers and reducing their partials must land on the same
+// weights as trainSequential's single-threaded full-batch descent.
+// Run with -race to exercise the worker pool's gradient reduction.
+func TestTrainConcurrentMatchesSequentialPerEpoch(t *testing.T) {
+	X := [][]float64{
+		{1, 0.1, 0.2},
+		{1, 0.4, 0.1},
+		{1, 0.9, 0.8},
+		{1, 0.3, 0.6},
+		{1, 0.7, 0.3},
+		{1, 0.2, 0.9},
+		{1, 0.6, 0.4},
+		{1, 0.8, 0.1},
+	}
+	y := []float64{0, 0, 1, 0, 1, 1, 1, 0}
+
+	const iterations = 200
+	const lr = 0.5
+
+	seqWeights := trainSequential(X, y, &optim.SGD{LR: lr}, nil, iterations, len(X))
+	concWeights := trainConcurrent(X, y, &optim.SGD{LR: lr}, nil, iterations, len(X), PerEpoch)
+
+	if len(seqWeights) != len(concWeights) {
+		t.Fatalf("weight length mismatch: sequential=%d concurrent=%d", len(seqWeights), len(concWeights))
+	}
+	for i := range seqWeights {
+		if diff := math.Abs(seqWeights[i] - concWeights[i]); diff > 1e-6 {
+			t.Errorf("weight[%d]: sequential=%v concurrent=%v diff=%v", i, seqWeights[i], concWeights[i], diff)
+		}
+	}
+}
@@ -1,12 +1,12 @@
 package main
 
 import (
-	"encoding/csv"
 	"fmt"
 	"math"
-	"os"
-	"strconv"
 	"time"
+
+	"tpconcurrente/data"
+	"tpconcurrente/optim"
 )
 
 // ----------- Funciones comunes -----------
@@ -23,69 +23,9 @@ func predict(X []float64, weights []float64) float64 {
 	return sigmoid(z)
 }
 
-func loadCSVData(path string) ([][]float64, []float64, float64, float64, float64, float64, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, nil, 0, 0, 0, 0, err
-	}
-	defer file.Close()
-
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, nil, 0, 0, 0, 0, err
-	}
-
-	var X [][]float64
-	var y []float64
-	minRating, maxRating := math.MaxFloat64, -math.MaxFloat64
-	minReviews, maxReviews := math.MaxFloat64, -math.MaxFloat64
-
-	for i, row := range records {
-		if i == 0 {
-			continue
-		}
-		rating, err1 := strconv.ParseFloat(row[5], 64)
-		numReviews, err2 := strconv.ParseFloat(row[6], 64)
-		if err1 != nil || err2 != nil {
-			continue
-		}
-
-		if rating < minRating {
-			minRating = rating
-		}
-		if rating > maxRating {
-			maxRating = rating
-		}
-		if numReviews < minReviews {
-			minReviews = numReviews
-		}
-		if numReviews > maxReviews {
-			maxReviews = numReviews
-		}
-
-		xi := []float64{1, rating, numReviews}
-		X = append(X, xi)
-
-		label := 0.0
-		if rating >= 4.0 {
-			label = 1.0
-		}
-		y = append(y, label)
-	}
-	return X, y, minRating, maxRating, minReviews, maxReviews, nil
-}
-
-func normalizeFeatures(X [][]float64, minRating, maxRating, minReviews, maxReviews float64) {
-	for i := 0; i < len(X); i++ {
-		X[i][1] = (X[i][1] - minRating) / (maxRating - minRating)
-		X[i][2] = (X[i][2] - minReviews) / (maxReviews - minReviews)
-	}
-}
-
 // ----------- Entrenamiento Secuencial -----------
 
-func trainSequential(X [][]float64, y []float64, learningRate float64, iterations int) []float64 {
+func trainSequential(X [][]float64, y []float64, opt optim.Optimizer, reg optim.Regularizer, iterations int) []float64 {
 	features := len(X[0])
 	weights := make([]float64, features)
 
@@ -99,8 +39,12 @@ func trainSequential(X [][]float64, y []float64, learningRate float64, iteration
 			}
 		}
 		for j := 0; j < features; j++ {
-			weights[j] -= learningRate * gradients[j] / float64(len(X))
+			gradients[j] /= float64(len(X))
+		}
+		if reg != nil {
+			reg.Penalize(weights, gradients)
 		}
+		opt.Step(weights, gradients)
 	}
 	return weights
 }
@@ -117,21 +61,23 @@ func calculateAccuracy(X [][]float64, y []float64, weights []float64) float64 {
 }
 
 func main() {
-	// Cargar datos desde el CSV
-	X, y, minRating, maxRating, minReviews, maxReviews, err := loadCSVData("yelp_database.csv")
+	// Cargar datos desde el CSV con el loader en streaming
+	loader := data.NewStreamingLoader()
+	X, y, err := loader.Load("yelp_database.csv")
 	if err != nil {
 		fmt.Println("Error al cargar datos:", err)
 		return
 	}
-	normalizeFeatures(X, minRating, maxRating, minReviews, maxReviews)
+	loader.Normalize(X, data.MinMax)
 
 	// Configuración de parámetros
-	learningRate := 0.1
 	iterations := 1000
+	opt := optim.NewMomentum(0.1, 0.9)
+	reg := optim.L2{Lambda: 0.01}
 
 	// Entrenamiento secuencial
 	start := time.Now()
-	weights := trainSequential(X, y, learningRate, iterations)
+	weights := trainSequential(X, y, opt, reg, iterations)
 	duration := time.Since(start)
 
 	// Cálculo de precisión
@@ -141,5 +87,6 @@ func main() {
 	fmt.Println("--- Modo Secuencial ---")
 	fmt.Printf("Pesos: %v\n", weights)
 	fmt.Printf("Precisión: %.2f%%\n", accuracy)
+	fmt.Printf("Pérdida L2: %.6f\n", reg.Loss(weights))
 	fmt.Printf("Tiempo de ejecución: %v\n", duration)
 }
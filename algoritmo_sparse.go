@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	"tpconcurrente/data"
+	"tpconcurrente/optim"
+	"tpconcurrente/sparse"
+)
+
+// ----------- Funciones comunes -----------
+
+func sigmoid(z float64) float64 {
+	return 1.0 / (1.0 + math.Exp(-z))
+}
+
+// predict computes sigmoid(w . x) over the nonzero entries of a
+// sparse feature vector, so cost is O(nnz) rather than O(len(weights)).
+func predict(x sparse.Vector, weights []float64) float64 {
+	return sigmoid(x.Dot(weights))
+}
+
+// ----------- Entrenamiento Concurrente (features dispersas) -----------
+
+// UpdateMode selects how often trainConcurrent applies a weight update.
+type UpdateMode int
+
+const (
+	// PerMinibatch applies one update per mini-batch, i.e. stochastic
+	// mini-batch gradient descent.
+	PerMinibatch UpdateMode = iota
+	// PerEpoch accumulates the gradient over the whole dataset before
+	// applying a single update, i.e. true batch gradient descent.
+	PerEpoch
+)
+
+// rowRange is a [start, end) slice of row indices dispatched to a worker.
+type rowRange struct {
+	start, end int
+}
+
+// trainConcurrent trains a logistic regression over hashed
+// bag-of-words features with the same fixed worker pool design as
+// the dense trainer: each worker pulls row ranges off a buffered
+// channel and accumulates its own partial gradient, keyed by hashed
+// feature index rather than a dense []float64 of length dim, so both
+// the per-example update and the inter-worker merge stay O(nnz).
+// Only the final reduction materializes a dense gradient of length
+// dim, which is then handed to opt and reg like the dense trainer.
+func trainConcurrent(X []sparse.Vector, y []float64, opt optim.Optimizer, reg optim.Regularizer, iterations int, batchSize int, mode UpdateMode, dim int) []float64 {
+	weights := make([]float64, dim)
+	dataLen := len(X)
+
+	workers := runtime.GOMAXPROCS(0)
+	jobs := make(chan rowRange, workers)
+	partials := make([]map[int]float64, workers)
+	for w := range partials {
+		partials[w] = make(map[int]float64)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		go func(id int) {
+			grad := partials[id]
+			for r := range jobs {
+				for j := r.start; j < r.end; j++ {
+					pred := predict(X[j], weights)
+					error := pred - y[j]
+					for i, idx := range X[j].Indices {
+						grad[idx] += error * X[j].Values[i]
+					}
+				}
+				wg.Done()
+			}
+		}(w)
+	}
+	defer close(jobs)
+
+	computeGradient := func(lo, hi int) []float64 {
+		for _, p := range partials {
+			for idx := range p {
+				delete(p, idx)
+			}
+		}
+
+		n := hi - lo
+		chunkSize := (n + workers - 1) / workers
+		for start := lo; start < hi; start += chunkSize {
+			end := start + chunkSize
+			if end > hi {
+				end = hi
+			}
+			wg.Add(1)
+			jobs <- rowRange{start, end}
+		}
+		wg.Wait()
+
+		grad := make([]float64, dim)
+		for _, p := range partials {
+			for idx, val := range p {
+				grad[idx] += val
+			}
+		}
+		for idx := range grad {
+			grad[idx] /= float64(n)
+		}
+		return grad
+	}
+
+	for iter := 0; iter < iterations; iter++ {
+		switch mode {
+		case PerEpoch:
+			grad := computeGradient(0, dataLen)
+			if reg != nil {
+				reg.Penalize(weights, grad)
+			}
+			opt.Step(weights, grad)
+		default: // PerMinibatch
+			for i := 0; i < dataLen; i += batchSize {
+				end := i + batchSize
+				if end > dataLen {
+					end = dataLen
+				}
+				grad := computeGradient(i, end)
+				if reg != nil {
+					reg.Penalize(weights, grad)
+				}
+				opt.Step(weights, grad)
+			}
+		}
+	}
+	return weights
+}
+
+func calculateAccuracy(X []sparse.Vector, y []float64, weights []float64) float64 {
+	correct := 0
+	for i := range X {
+		pred := predict(X[i], weights)
+		if (pred >= 0.5 && y[i] == 1.0) || (pred < 0.5 && y[i] == 0.0) {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(X)) * 100
+}
+
+func main() {
+	// Tokenizar las reviews del CSV en features dispersas (hashed bag-of-words)
+	X, y, err := data.TokenizeReviews("yelp_database.csv")
+	if err != nil {
+		fmt.Println("Error al tokenizar reviews:", err)
+		return
+	}
+
+	// Configuración de parámetros
+	iterations := 30
+	batchSize := 200
+	opt := &optim.SGD{LR: 0.5}
+	reg := optim.L2{Lambda: 0.0001}
+
+	// Entrenamiento concurrente sobre features de texto
+	start := time.Now()
+	weights := trainConcurrent(X, y, opt, reg, iterations, batchSize, PerMinibatch, data.HashedFeatureDim)
+	duration := time.Since(start)
+
+	// Cálculo de precisión
+	accuracy := calculateAccuracy(X, y, weights)
+
+	// Mostrar resultados
+	fmt.Println("--- Modo Concurrente (texto, hashed bag-of-words) ---")
+	fmt.Printf("Precisión: %.2f%%\n", accuracy)
+	fmt.Printf("Pérdida L2: %.6f\n", reg.Loss(weights))
+	fmt.Printf("Tiempo de ejecución: %v\n", duration)
+}
@@ -0,0 +1,255 @@
+// Package bench summarizes timing samples collected from the
+// benchmark menu option, and compares a sequential and a concurrent
+// sample with Welch's t-test.
+package bench
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Report summarizes a sample of durations: min, max, mean, standard
+// deviation and the p50/p90/p95/p99 percentiles.
+type Report struct {
+	N      int
+	Min    time.Duration
+	Max    time.Duration
+	Mean   time.Duration
+	StdDev time.Duration
+	Median time.Duration
+	P50    time.Duration
+	P90    time.Duration
+	P95    time.Duration
+	P99    time.Duration
+}
+
+// NewReport builds a Report from samples. samples is sorted in place.
+func NewReport(samples []time.Duration) Report {
+	if len(samples) == 0 {
+		return Report{}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	n := len(samples)
+	var sum time.Duration
+	for _, s := range samples {
+		sum += s
+	}
+	mean := sum / time.Duration(n)
+
+	var sqDiff float64
+	meanF := float64(mean)
+	for _, s := range samples {
+		d := float64(s) - meanF
+		sqDiff += d * d
+	}
+	var stdDev time.Duration
+	if n > 1 {
+		stdDev = time.Duration(math.Sqrt(sqDiff / float64(n-1)))
+	}
+
+	return Report{
+		N:      n,
+		Min:    samples[0],
+		Max:    samples[n-1],
+		Mean:   mean,
+		StdDev: stdDev,
+		Median: percentile(samples, 0.50),
+		P50:    percentile(samples, 0.50),
+		P90:    percentile(samples, 0.90),
+		P95:    percentile(samples, 0.95),
+		P99:    percentile(samples, 0.99),
+	}
+}
+
+// percentile returns the p-th percentile (0<=p<=1) of an already
+// sorted sample, via linear interpolation between nearest ranks.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := p * float64(len(sorted)-1)
+	lo := int(math.Floor(idx))
+	hi := int(math.Ceil(idx))
+	if lo == hi {
+		return sorted[lo]
+	}
+	frac := idx - float64(lo)
+	return sorted[lo] + time.Duration(frac*float64(sorted[hi]-sorted[lo]))
+}
+
+// PrintRow prints r as one row of the compact table printed by
+// Comparison.Print.
+func (r Report) PrintRow(label string) {
+	fmt.Printf("%-12s n=%-6d min=%-12v max=%-12v mean=%-12v sd=%-12v p50=%-12v p90=%-12v p95=%-12v p99=%-12v\n",
+		label, r.N, r.Min, r.Max, r.Mean, r.StdDev, r.P50, r.P90, r.P95, r.P99)
+}
+
+// CSV renders the report as one CSV row (durations in seconds) so
+// results can be plotted externally.
+func (r Report) CSV(label string) string {
+	return fmt.Sprintf("%s,%d,%.6f,%.6f,%.6f,%.6f,%.6f,%.6f,%.6f,%.6f",
+		label, r.N, r.Min.Seconds(), r.Max.Seconds(), r.Mean.Seconds(), r.StdDev.Seconds(),
+		r.Median.Seconds(), r.P90.Seconds(), r.P95.Seconds(), r.P99.Seconds())
+}
+
+// Comparison holds the Reports for a sequential and a concurrent
+// sample, plus the statistics needed to judge whether the concurrent
+// sample is a genuine speedup rather than noise.
+type Comparison struct {
+	Sequential Report
+	Concurrent Report
+	PValue     float64       // Welch's t-test, two-tailed
+	CILow      time.Duration // 95% CI lower bound on the mean speedup
+	CIHigh     time.Duration // 95% CI upper bound on the mean speedup
+}
+
+// Compare builds Reports for both samples, then runs Welch's t-test
+// and a 95% confidence interval on the mean speedup
+// (mean(sequential) - mean(concurrent)).
+func Compare(sequential, concurrent []time.Duration) Comparison {
+	seq := NewReport(append([]time.Duration(nil), sequential...))
+	conc := NewReport(append([]time.Duration(nil), concurrent...))
+
+	n1, n2 := float64(seq.N), float64(conc.N)
+	sd1, sd2 := float64(seq.StdDev), float64(conc.StdDev)
+	v1, v2 := sd1*sd1/n1, sd2*sd2/n2
+
+	se := math.Sqrt(v1 + v2)
+	meanDiff := float64(seq.Mean) - float64(conc.Mean)
+
+	var pValue float64 = 1
+	if se > 0 {
+		t := meanDiff / se
+		df := welchDF(v1, n1, v2, n2)
+		pValue = welchPValue(t, df)
+	}
+
+	margin := 1.96 * se
+	return Comparison{
+		Sequential: seq,
+		Concurrent: conc,
+		PValue:     pValue,
+		CILow:      time.Duration(meanDiff - margin),
+		CIHigh:     time.Duration(meanDiff + margin),
+	}
+}
+
+// Print prints the comparison as a compact table followed by the
+// Welch's t-test p-value and the 95% CI on the mean speedup.
+func (c Comparison) Print() {
+	c.Sequential.PrintRow("secuencial")
+	c.Concurrent.PrintRow("concurrente")
+	fmt.Printf("p-value (Welch t-test): %.4f\n", c.PValue)
+	fmt.Printf("IC 95%% del speedup medio: [%v, %v]\n", c.CILow, c.CIHigh)
+}
+
+// CSVRows renders the comparison as CSV lines (header plus one row per
+// sample) so results can be plotted externally.
+func (c Comparison) CSVRows() []string {
+	return []string{
+		"label,n,min,max,mean,sd,median,p90,p95,p99",
+		c.Sequential.CSV("secuencial"),
+		c.Concurrent.CSV("concurrente"),
+	}
+}
+
+// welchDF is the Welch-Satterthwaite approximation for the degrees of
+// freedom of two samples with unequal variance, given their variance
+// of the mean v1=sd1²/n1 and v2=sd2²/n2.
+func welchDF(v1, n1, v2, n2 float64) float64 {
+	num := (v1 + v2) * (v1 + v2)
+	den := v1*v1/(n1-1) + v2*v2/(n2-1)
+	if den == 0 {
+		return 1
+	}
+	return num / den
+}
+
+// welchPValue returns the two-tailed p-value of t-statistic t with df
+// degrees of freedom, via the regularized incomplete beta function.
+func welchPValue(t, df float64) float64 {
+	if df <= 0 {
+		return 1
+	}
+	x := df / (df + t*t)
+	return incompleteBeta(x, df/2, 0.5)
+}
+
+// incompleteBeta is the regularized incomplete beta function I_x(a, b),
+// evaluated via its continued fraction expansion (Numerical Recipes
+// §6.4). This is the building block welchPValue needs for the
+// Student's t CDF.
+func incompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lnBeta, _ := math.Lgamma(a + b)
+	lgA, _ := math.Lgamma(a)
+	lgB, _ := math.Lgamma(b)
+	lnBeta = lnBeta - lgA - lgB + a*math.Log(x) + b*math.Log(1-x)
+	front := math.Exp(lnBeta)
+
+	if x < (a+1)/(a+b+2) {
+		return front * betacf(x, a, b) / a
+	}
+	return 1 - front*betacf(1-x, b, a)/b
+}
+
+// betacf evaluates the continued fraction used by incompleteBeta,
+// truncated once successive terms stop changing the result.
+func betacf(x, a, b float64) float64 {
+	const maxIter = 200
+	const epsilon = 1e-10
+	const tiny = 1e-30
+
+	qab := a + b
+	qap := a + 1
+	qam := a - 1
+	c := 1.0
+	d := 1 - qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+
+	for m := 1; m <= maxIter; m++ {
+		m2 := float64(2 * m)
+
+		aa := float64(m) * (b - float64(m)) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		aa = -(a + float64(m)) * (qab + float64(m)) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+
+		if math.Abs(del-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
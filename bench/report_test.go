@@ -0,0 +1,64 @@
+package bench
+
+import "testing"
+
+// TestIncompleteBetaUniform checks the textbook identity I_x(1,1) = x,
+// since Beta(1,1) is the uniform distribution and its CDF is the
+// identity function.
+func TestIncompleteBetaUniform(t *testing.T) {
+	for _, x := range []float64{0.1, 0.3, 0.5, 0.7, 0.9} {
+		got := incompleteBeta(x, 1, 1)
+		if diff := got - x; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("incompleteBeta(%v, 1, 1) = %v, want %v", x, got, x)
+		}
+	}
+}
+
+// TestIncompleteBetaSymmetry checks I_x(a, b) + I_{1-x}(b, a) = 1,
+// a standard identity of the regularized incomplete beta function.
+func TestIncompleteBetaSymmetry(t *testing.T) {
+	cases := []struct{ x, a, b float64 }{
+		{0.3, 2, 5}, {0.6, 3, 3}, {0.2, 10, 1.5},
+	}
+	for _, c := range cases {
+		sum := incompleteBeta(c.x, c.a, c.b) + incompleteBeta(1-c.x, c.b, c.a)
+		if diff := sum - 1; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("incompleteBeta(%v,%v,%v)+incompleteBeta(%v,%v,%v) = %v, want 1", c.x, c.a, c.b, 1-c.x, c.b, c.a, sum)
+		}
+	}
+}
+
+// TestWelchPValueCriticalValues checks welchPValue against the
+// textbook two-tailed 5% critical t-values (Student's t table) for a
+// range of degrees of freedom: feeding the table's critical t at a
+// given df should recover p ≈ 0.05.
+func TestWelchPValueCriticalValues(t *testing.T) {
+	cases := []struct {
+		df, t float64
+	}{
+		{1, 12.706},
+		{2, 4.303},
+		{5, 2.571},
+		{10, 2.228},
+		{20, 2.086},
+		{30, 2.042},
+		{60, 2.000},
+		{120, 1.980},
+	}
+	const want = 0.05
+	const tolerance = 0.002
+	for _, c := range cases {
+		got := welchPValue(c.t, c.df)
+		if diff := got - want; diff > tolerance || diff < -tolerance {
+			t.Errorf("welchPValue(%v, df=%v) = %v, want ~%v (±%v)", c.t, c.df, got, want, tolerance)
+		}
+	}
+}
+
+// TestWelchPValueAtZero checks that a zero t-statistic (identical
+// means) returns a p-value of 1, the two-tailed upper bound.
+func TestWelchPValueAtZero(t *testing.T) {
+	if got := welchPValue(0, 10); got != 1 {
+		t.Errorf("welchPValue(0, 10) = %v, want 1", got)
+	}
+}
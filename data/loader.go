@@ -0,0 +1,158 @@
+// Package data provides streaming CSV loading and online feature
+// statistics for the training programs in this module.
+package data
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"os"
+	"strconv"
+)
+
+// NormalizationMode selects how StreamingLoader rescales a feature.
+type NormalizationMode int
+
+const (
+	// MinMax rescales a feature to [0, 1] using its observed min/max.
+	MinMax NormalizationMode = iota
+	// ZScore rescales a feature to zero mean, unit variance.
+	ZScore
+)
+
+// FeatureStats accumulates min, max, mean and variance for a single
+// feature online, via Welford's algorithm, so no second pass over the
+// data is needed to normalize it.
+type FeatureStats struct {
+	Min, Max float64
+
+	n    int
+	mean float64
+	m2   float64
+}
+
+func newFeatureStats() *FeatureStats {
+	return &FeatureStats{Min: math.MaxFloat64, Max: -math.MaxFloat64}
+}
+
+func (s *FeatureStats) update(x float64) {
+	if x < s.Min {
+		s.Min = x
+	}
+	if x > s.Max {
+		s.Max = x
+	}
+
+	s.n++
+	delta := x - s.mean
+	s.mean += delta / float64(s.n)
+	s.m2 += delta * (x - s.mean)
+}
+
+// Mean returns the running mean of the observed values.
+func (s *FeatureStats) Mean() float64 {
+	return s.mean
+}
+
+// Variance returns the sample variance (Bessel-corrected).
+func (s *FeatureStats) Variance() float64 {
+	if s.n < 2 {
+		return 0
+	}
+	return s.m2 / float64(s.n-1)
+}
+
+// StdDev returns the sample standard deviation.
+func (s *FeatureStats) StdDev() float64 {
+	return math.Sqrt(s.Variance())
+}
+
+// StreamingLoader reads a Yelp-style CSV row-by-row instead of loading
+// it with csv.Reader.ReadAll, so datasets larger than RAM can be used
+// for training. It keeps online min/max/mean/variance statistics for
+// the rating and numReviews columns as it streams, so Normalize can
+// rescale either feature without a second pass over the file.
+type StreamingLoader struct {
+	Rating     *FeatureStats
+	NumReviews *FeatureStats
+}
+
+// NewStreamingLoader returns a StreamingLoader ready to accumulate
+// stats from a call to Load.
+func NewStreamingLoader() *StreamingLoader {
+	return &StreamingLoader{Rating: newFeatureStats(), NumReviews: newFeatureStats()}
+}
+
+// Load streams path row by row, building the feature matrix X and the
+// label vector y, while updating Rating and NumReviews stats.
+func (l *StreamingLoader) Load(path string) (X [][]float64, y []float64, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	first := true
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if first {
+			first = false
+			continue
+		}
+
+		rating, err1 := strconv.ParseFloat(row[5], 64)
+		numReviews, err2 := strconv.ParseFloat(row[6], 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+
+		l.Rating.update(rating)
+		l.NumReviews.update(numReviews)
+
+		X = append(X, []float64{1, rating, numReviews})
+
+		label := 0.0
+		if rating >= 4.0 {
+			label = 1.0
+		}
+		y = append(y, label)
+	}
+	return X, y, nil
+}
+
+// Normalize rescales the rating and numReviews columns of X in place,
+// using the statistics accumulated by Load and the given mode.
+func (l *StreamingLoader) Normalize(X [][]float64, mode NormalizationMode) {
+	for i := range X {
+		switch mode {
+		case ZScore:
+			X[i][1] = zscore(X[i][1], l.Rating)
+			X[i][2] = zscore(X[i][2], l.NumReviews)
+		default:
+			X[i][1] = minMax(X[i][1], l.Rating)
+			X[i][2] = minMax(X[i][2], l.NumReviews)
+		}
+	}
+}
+
+func minMax(x float64, s *FeatureStats) float64 {
+	if s.Max == s.Min {
+		return 0
+	}
+	return (x - s.Min) / (s.Max - s.Min)
+}
+
+func zscore(x float64, s *FeatureStats) float64 {
+	sd := s.StdDev()
+	if sd == 0 {
+		return 0
+	}
+	return (x - s.Mean()) / sd
+}
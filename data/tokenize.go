@@ -0,0 +1,90 @@
+package data
+
+import (
+	"encoding/csv"
+	"hash/fnv"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"tpconcurrente/sparse"
+)
+
+// HashedFeatureDim is the fixed dimension D that TokenizeReviews
+// hashes tokens into. Using a fixed D lets the feature space be
+// known ahead of time without a vocabulary pass over the data, at
+// the cost of accepting occasional hash collisions (the "hashing
+// trick", Weinberger et al. 2009).
+const HashedFeatureDim = 1 << 18
+
+// TokenizeReviews streams path row by row and builds one hashed
+// bag-of-words sparse.Vector per review: every whitespace-separated
+// token in the review text column is hashed with 32-bit FNV-1a into
+// [1, HashedFeatureDim), and the value at that index is incremented by
+// 1 per occurrence. Index 0 is reserved for an explicit bias term (set
+// to 1 on every row), matching the bias column StreamingLoader.Load
+// prepends to its dense X. The label is 1 when the row's rating is
+// >= 4.0, matching StreamingLoader.Load.
+func TokenizeReviews(path string) (X []sparse.Vector, y []float64, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	first := true
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if first {
+			first = false
+			continue
+		}
+
+		rating, err1 := strconv.ParseFloat(row[5], 64)
+		if err1 != nil {
+			continue
+		}
+
+		X = append(X, hashTokens(row[4]))
+
+		label := 0.0
+		if rating >= 4.0 {
+			label = 1.0
+		}
+		y = append(y, label)
+	}
+	return X, y, nil
+}
+
+// hashTokens builds a hashed bag-of-words sparse.Vector from text,
+// accumulating repeated tokens into the same hashed index. Tokens are
+// hashed into [1, HashedFeatureDim) so they never collide with the
+// bias term reserved at index 0.
+func hashTokens(text string) sparse.Vector {
+	counts := make(map[int]float64)
+	counts[0] = 1 // bias term
+	for _, token := range strings.Fields(text) {
+		h := fnv.New32a()
+		h.Write([]byte(token))
+		idx := 1 + int(h.Sum32()%(HashedFeatureDim-1))
+		counts[idx]++
+	}
+
+	v := sparse.Vector{
+		Indices: make([]int, 0, len(counts)),
+		Values:  make([]float64, 0, len(counts)),
+	}
+	for idx, count := range counts {
+		v.Indices = append(v.Indices, idx)
+		v.Values = append(v.Values, count)
+	}
+	return v
+}
@@ -0,0 +1,134 @@
+// Package dataset provides stratified train/test splitting and
+// k-fold cross-validation for the logistic regression trainers in
+// this module.
+package dataset
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// StratifiedSplit partitions X/y into len(ratios) folds that each
+// preserve the overall class balance, unlike slicing the CSV by row
+// order. Rows are bucketed by label, and buckets are visited in
+// ascending label order (not map iteration order, which is randomized
+// per run) so the same seed always draws the same sequence of shuffles
+// from rng and reproduces the same split. Each bucket is shuffled with
+// that shared rng and then cut according to ratios and interleaved
+// into the output folds. ratios must sum to 1.
+func StratifiedSplit(X [][]float64, y []float64, ratios []float64, seed int64) (foldX [][][]float64, foldY [][]float64) {
+	rng := rand.New(rand.NewSource(seed))
+
+	buckets := map[float64][]int{}
+	for i, label := range y {
+		buckets[label] = append(buckets[label], i)
+	}
+
+	// Map iteration order is randomized per run, and every bucket draws
+	// from the same rng, so buckets must be visited in a fixed order or
+	// the same seed would produce a different split from run to run.
+	labels := make([]float64, 0, len(buckets))
+	for label := range buckets {
+		labels = append(labels, label)
+	}
+	sort.Float64s(labels)
+
+	foldX = make([][][]float64, len(ratios))
+	foldY = make([][]float64, len(ratios))
+
+	for _, label := range labels {
+		indices := buckets[label]
+		rng.Shuffle(len(indices), func(i, j int) {
+			indices[i], indices[j] = indices[j], indices[i]
+		})
+
+		start := 0
+		for f, ratio := range ratios {
+			count := int(math.Round(ratio * float64(len(indices))))
+			end := start + count
+			if f == len(ratios)-1 || end > len(indices) {
+				end = len(indices)
+			}
+			for _, idx := range indices[start:end] {
+				foldX[f] = append(foldX[f], X[idx])
+				foldY[f] = append(foldY[f], y[idx])
+			}
+			start = end
+		}
+	}
+
+	return foldX, foldY
+}
+
+// KFoldCV runs k-fold cross-validation over X/y: it stratified-splits
+// the data into k folds, and for each fold trains on the remaining
+// k-1 folds with trainer and scores the held-out fold by thresholding
+// predict at 0.5. It returns the mean and standard deviation (Bessel
+// bias correction) of the per-fold accuracy, matching standard
+// practice for reporting logistic regression performance.
+func KFoldCV(
+	X [][]float64,
+	y []float64,
+	k int,
+	seed int64,
+	trainer func(trainX [][]float64, trainY []float64) []float64,
+	predict func(x []float64, weights []float64) float64,
+) (meanAcc, stdAcc float64) {
+	ratios := make([]float64, k)
+	for i := range ratios {
+		ratios[i] = 1.0 / float64(k)
+	}
+	foldX, foldY := StratifiedSplit(X, y, ratios, seed)
+
+	accs := make([]float64, k)
+	for i := 0; i < k; i++ {
+		var trainX [][]float64
+		var trainY []float64
+		for j := 0; j < k; j++ {
+			if j == i {
+				continue
+			}
+			trainX = append(trainX, foldX[j]...)
+			trainY = append(trainY, foldY[j]...)
+		}
+
+		weights := trainer(trainX, trainY)
+		accs[i] = accuracy(foldX[i], foldY[i], weights, predict)
+	}
+
+	meanAcc = mean(accs)
+	stdAcc = stdDev(accs, meanAcc)
+	return meanAcc, stdAcc
+}
+
+func accuracy(X [][]float64, y []float64, weights []float64, predict func(x []float64, weights []float64) float64) float64 {
+	correct := 0
+	for i := range X {
+		pred := predict(X[i], weights)
+		if (pred >= 0.5 && y[i] == 1.0) || (pred < 0.5 && y[i] == 0.0) {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(X)) * 100
+}
+
+func mean(xs []float64) float64 {
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+func stdDev(xs []float64, mean float64) float64 {
+	if len(xs) < 2 {
+		return 0
+	}
+	var sqDiff float64
+	for _, x := range xs {
+		d := x - mean
+		sqDiff += d * d
+	}
+	return math.Sqrt(sqDiff / float64(len(xs)-1))
+}
@@ -0,0 +1,91 @@
+package dataset
+
+import "testing"
+
+// TestStratifiedSplitPreservesClassBalance builds an imbalanced
+// dataset (80 rows of label 0, 20 of label 1) and checks that each
+// fold's per-class proportion matches the overall proportion within
+// rounding, and that every row ends up in exactly one fold.
+func TestStratifiedSplitPreservesClassBalance(t *testing.T) {
+	const n0, n1 = 80, 20
+	var X [][]float64
+	var y []float64
+	for i := 0; i < n0; i++ {
+		X = append(X, []float64{float64(i)})
+		y = append(y, 0)
+	}
+	for i := 0; i < n1; i++ {
+		X = append(X, []float64{float64(n0 + i)})
+		y = append(y, 1)
+	}
+
+	ratios := []float64{0.7, 0.3}
+	foldX, foldY := StratifiedSplit(X, y, ratios, 42)
+
+	if len(foldX) != len(ratios) || len(foldY) != len(ratios) {
+		t.Fatalf("got %d/%d folds, want %d", len(foldX), len(foldY), len(ratios))
+	}
+
+	totalByLabel := map[float64]int{0: n0, 1: n1}
+	gotByLabel := map[float64]int{0: 0, 1: 0}
+
+	for f, ratio := range ratios {
+		if len(foldX[f]) != len(foldY[f]) {
+			t.Fatalf("fold %d: len(X)=%d != len(y)=%d", f, len(foldX[f]), len(foldY[f]))
+		}
+
+		counts := map[float64]int{0: 0, 1: 0}
+		for _, label := range foldY[f] {
+			counts[label]++
+			gotByLabel[label]++
+		}
+
+		for label, total := range totalByLabel {
+			want := ratio * float64(total)
+			if diff := float64(counts[label]) - want; diff > 1 || diff < -1 {
+				t.Errorf("fold %d: label %v has %d rows, want ~%v (ratio %v of %d)",
+					f, label, counts[label], want, ratio, total)
+			}
+		}
+	}
+
+	// Every row from every class must land in exactly one fold: no
+	// row dropped or duplicated across folds.
+	for label, total := range totalByLabel {
+		if gotByLabel[label] != total {
+			t.Errorf("label %v: folds contain %d rows total, want %d", label, gotByLabel[label], total)
+		}
+	}
+}
+
+// TestStratifiedSplitDeterministic checks that the same seed always
+// produces the same split, across many runs. Buckets are drawn from a
+// single shared rng in label order, so this would be flaky if that
+// order ever depended on Go's randomized map iteration order instead.
+func TestStratifiedSplitDeterministic(t *testing.T) {
+	const n = 30
+	var X [][]float64
+	var y []float64
+	for i := 0; i < n; i++ {
+		X = append(X, []float64{float64(i)})
+		y = append(y, float64(i%3)) // three classes, so >1 bucket order to get wrong
+	}
+
+	ratios := []float64{0.6, 0.4}
+	wantX, _ := StratifiedSplit(X, y, ratios, 42)
+
+	for run := 0; run < 20; run++ {
+		gotX, _ := StratifiedSplit(X, y, ratios, 42)
+		for f := range wantX {
+			if len(gotX[f]) != len(wantX[f]) {
+				t.Fatalf("run %d: fold %d has %d rows, want %d", run, f, len(gotX[f]), len(wantX[f]))
+			}
+			for i := range wantX[f] {
+				if gotX[f][i][0] != wantX[f][i][0] {
+					t.Fatalf("run %d: fold %d row %d = %v, want %v (same seed must reproduce the same split)",
+						run, f, i, gotX[f][i][0], wantX[f][i][0])
+				}
+			}
+		}
+	}
+}
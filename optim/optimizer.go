@@ -0,0 +1,122 @@
+// Package optim provides pluggable gradient-descent optimizers and
+// L2 regularization for the logistic regression trainers in this
+// module.
+package optim
+
+import "math"
+
+// Optimizer applies one gradient step to weights in place. grad is
+// the (already batch-averaged) gradient of the loss with respect to
+// weights.
+type Optimizer interface {
+	Step(weights, grad []float64)
+}
+
+// SGD is vanilla gradient descent: w -= lr*g.
+type SGD struct {
+	LR float64
+}
+
+// Step applies one vanilla SGD update.
+func (o *SGD) Step(weights, grad []float64) {
+	for j := range weights {
+		weights[j] -= o.LR * grad[j]
+	}
+}
+
+// Momentum is SGD with a momentum term: v = mu*v - lr*g; w += v. The
+// velocity v is lazily sized to len(weights) on the first Step call.
+type Momentum struct {
+	LR float64
+	Mu float64
+
+	v []float64
+}
+
+// NewMomentum returns a Momentum optimizer with the given learning
+// rate and momentum coefficient.
+func NewMomentum(lr, mu float64) *Momentum {
+	return &Momentum{LR: lr, Mu: mu}
+}
+
+// Step applies one SGD-with-momentum update.
+func (o *Momentum) Step(weights, grad []float64) {
+	if o.v == nil {
+		o.v = make([]float64, len(weights))
+	}
+	for j := range weights {
+		o.v[j] = o.Mu*o.v[j] - o.LR*grad[j]
+		weights[j] += o.v[j]
+	}
+}
+
+// Adam implements the Adam optimizer (Kingma & Ba, 2014): first and
+// second moment estimates m/v, bias-corrected by the step count t.
+// The moment vectors are lazily sized to len(weights) on the first
+// Step call.
+type Adam struct {
+	LR      float64
+	Beta1   float64
+	Beta2   float64
+	Epsilon float64
+
+	t    int
+	m, v []float64
+}
+
+// NewAdam returns an Adam optimizer with the given learning rate and
+// the standard beta1=0.9, beta2=0.999, epsilon=1e-8 defaults.
+func NewAdam(lr float64) *Adam {
+	return &Adam{LR: lr, Beta1: 0.9, Beta2: 0.999, Epsilon: 1e-8}
+}
+
+// Step applies one Adam update.
+func (o *Adam) Step(weights, grad []float64) {
+	if o.m == nil {
+		o.m = make([]float64, len(weights))
+		o.v = make([]float64, len(weights))
+	}
+	o.t++
+
+	for j := range weights {
+		o.m[j] = o.Beta1*o.m[j] + (1-o.Beta1)*grad[j]
+		o.v[j] = o.Beta2*o.v[j] + (1-o.Beta2)*grad[j]*grad[j]
+
+		mHat := o.m[j] / (1 - math.Pow(o.Beta1, float64(o.t)))
+		vHat := o.v[j] / (1 - math.Pow(o.Beta2, float64(o.t)))
+
+		weights[j] -= o.LR * mHat / (math.Sqrt(vHat) + o.Epsilon)
+	}
+}
+
+// Regularizer folds a penalty into the gradient during training and
+// reports the corresponding loss term, so training can report the
+// regularized objective instead of just the raw cross-entropy loss.
+type Regularizer interface {
+	// Penalize adds the penalty gradient onto grad in place.
+	Penalize(weights, grad []float64)
+	// Loss returns the penalty term for the current weights.
+	Loss(weights []float64) float64
+}
+
+// L2 is L2 (ridge) regularization: it folds lambda*w[j] into the
+// gradient, skipping the bias term at index 0.
+type L2 struct {
+	Lambda float64
+}
+
+// Penalize adds lambda*w[j] to grad[j] for every weight but the bias.
+func (r L2) Penalize(weights, grad []float64) {
+	for j := 1; j < len(weights); j++ {
+		grad[j] += r.Lambda * weights[j]
+	}
+}
+
+// Loss returns 0.5*lambda*sum(w[j]^2), skipping the bias term.
+func (r L2) Loss(weights []float64) float64 {
+	var sum float64
+	for j := 1; j < len(weights); j++ {
+		sum += weights[j] * weights[j]
+	}
+	return 0.5 * r.Lambda * sum
+}
@@ -0,0 +1,23 @@
+// Package sparse provides a sparse feature vector for the hashed
+// bag-of-words features built from Yelp review text, so predicting
+// and accumulating the gradient for one example costs O(nnz) rather
+// than O(D).
+package sparse
+
+// Vector is a sparse feature vector: Values[i] is the value at
+// feature index Indices[i]. Indices need not be sorted, but must not
+// repeat within a single Vector.
+type Vector struct {
+	Indices []int
+	Values  []float64
+}
+
+// Dot returns the dot product of v with a dense weight vector,
+// visiting only v's nonzero entries.
+func (v Vector) Dot(weights []float64) float64 {
+	var sum float64
+	for i, idx := range v.Indices {
+		sum += v.Values[i] * weights[idx]
+	}
+	return sum
+}